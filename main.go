@@ -1,57 +1,612 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"math"
+	"math/rand"
 	"net/http"
+	"net/netip"
 	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/beevik/ntp"
 	"github.com/regiellis/obs-timecode-go/server"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 var (
 	port        int
 	defaultFps  int
 	timeService *server.TimecodeService
-	ntpServer   string
-	debug       bool // Add debug flag
+	ntpServers  []string
+	logLevel    string
+	logFormat   string
+	appLogger   *zap.Logger
+
+	trustedProxyCIDRs     []string
+	trustForwardedHeaders bool
+
+	tlsCertFile  string
+	tlsKeyFile   string
+	acmeDomain   string
+	acmeEmail    string
+	acmeCacheDir string
+	hstsEnabled  bool
+
+	metricsEnabled  bool
+	metricsRegistry *prometheus.Registry
+	appMetrics      *server.Metrics
 )
 
-// NTP sync interval in seconds
-type ntpTimeProvider struct {
-	server   string
-	lastSync time.Time
-	offset   time.Duration
+// parseTrustedProxies parses --trusted-proxies CIDRs into netip.Prefix
+// values. It returns an empty slice (never nil) when
+// trustForwardedHeaders is false, so ClientIP's X-Real-IP/X-Forwarded-For
+// handling is disabled end-to-end unless both flags are set.
+func parseTrustedProxies(cidrs []string, enabled bool) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	if !enabled {
+		return prefixes, nil
+	}
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --trusted-proxies entry %q: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+// buildLogger constructs the process-wide zap logger from the --log-level
+// and --log-format flags. format is "json" for machine-readable output or
+// "console" for human-readable output during local development.
+func buildLogger(level, format string) (*zap.Logger, error) {
+	var zapLevel zapcore.Level
+	if err := zapLevel.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	var cfg zap.Config
+	switch format {
+	case "json":
+		cfg = zap.NewProductionConfig()
+	case "console":
+		cfg = zap.NewDevelopmentConfig()
+	default:
+		return nil, fmt.Errorf("invalid log format %q (want json or console)", format)
+	}
+	cfg.Level = zap.NewAtomicLevelAt(zapLevel)
+
+	return cfg.Build()
+}
+
+// NTP pool tuning constants
+const (
+	ntpSyncInterval   = 60 * time.Second
+	ntpSyncJitter     = 5 * time.Second
+	ntpMaxFailures    = 3
+	ntpCooldownPeriod = 5 * time.Minute
+)
+
+// ntpServerStats tracks the health of a single configured NTP server.
+type ntpServerStats struct {
+	mu               sync.Mutex
+	addr             string
+	offset           time.Duration
+	lastSync         time.Time
+	lastErr          error
+	consecutiveFails int
+	successCount     int
+	failureCount     int
+	cooldownUntil    time.Time
+}
+
+func (s *ntpServerStats) inCooldown(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.cooldownUntil.IsZero() && now.Before(s.cooldownUntil)
+}
+
+func (s *ntpServerStats) recordSuccess(offset time.Duration, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.offset = offset
+	s.lastSync = now
+	s.lastErr = nil
+	s.consecutiveFails = 0
+	s.successCount++
+	s.cooldownUntil = time.Time{}
+}
+
+func (s *ntpServerStats) recordFailure(err error, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastErr = err
+	s.failureCount++
+	s.consecutiveFails++
+	if s.consecutiveFails >= ntpMaxFailures {
+		s.cooldownUntil = now.Add(ntpCooldownPeriod)
+	}
+}
+
+func (s *ntpServerStats) snapshot() ntpServerHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := ntpServerHealth{
+		Server:           s.addr,
+		OffsetMs:         float64(s.offset) / float64(time.Millisecond),
+		SuccessCount:     s.successCount,
+		FailureCount:     s.failureCount,
+		ConsecutiveFails: s.consecutiveFails,
+		InCooldown:       !s.cooldownUntil.IsZero() && time.Now().Before(s.cooldownUntil),
+	}
+	if !s.lastSync.IsZero() {
+		h.LastSyncAt = s.lastSync
+	}
+	if s.lastErr != nil {
+		h.LastError = s.lastErr.Error()
+	}
+	return h
 }
 
-func newNtpTimeProvider(server string) *ntpTimeProvider {
-	return &ntpTimeProvider{server: server}
+// ntpServerHealth is the JSON-serializable view of a single server's health.
+type ntpServerHealth struct {
+	Server           string    `json:"server"`
+	OffsetMs         float64   `json:"offset_ms"`
+	LastSyncAt       time.Time `json:"last_sync_at,omitempty"`
+	LastError        string    `json:"last_error,omitempty"`
+	SuccessCount     int       `json:"success_count"`
+	FailureCount     int       `json:"failure_count"`
+	ConsecutiveFails int       `json:"consecutive_fails"`
+	InCooldown       bool      `json:"in_cooldown"`
+}
+
+// ntpHealthReport is returned by the /health endpoint.
+type ntpHealthReport struct {
+	ActiveServers []string          `json:"active_servers"`
+	Offset        float64           `json:"offset_ms"`
+	DriftMs       float64           `json:"drift_ms"`
+	LastSyncAt    time.Time         `json:"last_sync_at,omitempty"`
+	Servers       []ntpServerHealth `json:"servers"`
+}
+
+// NTPPool queries multiple NTP servers on each sync interval, discards
+// outlying offsets, and averages the rest weighted by stratum/dispersion.
+// It re-syncs periodically in the background, jittered to avoid thundering
+// herds against the upstream pool, and evicts misbehaving servers into a
+// cooldown after repeated consecutive failures.
+type NTPPool struct {
+	mu         sync.RWMutex
+	servers    []*ntpServerStats
+	offset     time.Duration
+	prevOffset time.Duration
+	lastSyncAt time.Time
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	logger   *zap.Logger
+}
+
+var (
+	ntpOffsetSecondsDesc = prometheus.NewDesc(
+		"obs_timecode_ntp_offset_seconds",
+		"Current NTP pool clock offset in seconds.",
+		nil, nil,
+	)
+	ntpSecondsSinceSyncDesc = prometheus.NewDesc(
+		"obs_timecode_ntp_seconds_since_sync",
+		"Seconds since the NTP pool's last successful sync.",
+		nil, nil,
+	)
+	ntpServerSuccessDesc = prometheus.NewDesc(
+		"obs_timecode_ntp_server_success_total",
+		"Successful NTP queries, labeled by server.",
+		[]string{"server"}, nil,
+	)
+	ntpServerFailureDesc = prometheus.NewDesc(
+		"obs_timecode_ntp_server_failure_total",
+		"Failed NTP queries, labeled by server.",
+		[]string{"server"}, nil,
+	)
+)
+
+// Describe implements prometheus.Collector.
+func (p *NTPPool) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(p, ch)
 }
 
-func (n *ntpTimeProvider) Now() time.Time {
-	if n.offset == 0 || time.Since(n.lastSync) > 10*time.Minute {
-		offset, err := getNtpOffset(n.server)
-		if err == nil {
-			n.offset = offset
-			n.lastSync = time.Now()
-		} else {
-			log.Printf("[WARN] NTP sync failed: %v, using system time", err)
+// Collect implements prometheus.Collector, computing every NTP gauge/counter
+// live from the pool's current state rather than maintaining them
+// incrementally alongside sync().
+func (p *NTPPool) Collect(ch chan<- prometheus.Metric) {
+	p.mu.RLock()
+	offset := p.offset
+	lastSyncAt := p.lastSyncAt
+	p.mu.RUnlock()
+
+	ch <- prometheus.MustNewConstMetric(ntpOffsetSecondsDesc, prometheus.GaugeValue, offset.Seconds())
+
+	secondsSinceSync := 0.0
+	if !lastSyncAt.IsZero() {
+		secondsSinceSync = time.Since(lastSyncAt).Seconds()
+	}
+	ch <- prometheus.MustNewConstMetric(ntpSecondsSinceSyncDesc, prometheus.GaugeValue, secondsSinceSync)
+
+	for _, s := range p.servers {
+		h := s.snapshot()
+		ch <- prometheus.MustNewConstMetric(ntpServerSuccessDesc, prometheus.CounterValue, float64(h.SuccessCount), h.Server)
+		ch <- prometheus.MustNewConstMetric(ntpServerFailureDesc, prometheus.CounterValue, float64(h.FailureCount), h.Server)
+	}
+}
+
+func newNTPPool(servers []string, logger *zap.Logger) *NTPPool {
+	stats := make([]*ntpServerStats, 0, len(servers))
+	for _, s := range servers {
+		stats = append(stats, &ntpServerStats{addr: s})
+	}
+	return &NTPPool{
+		servers: stats,
+		stopCh:  make(chan struct{}),
+		logger:  logger,
+	}
+}
+
+// Now returns the current time adjusted by the pool's last-known offset.
+func (p *NTPPool) Now() time.Time {
+	p.mu.RLock()
+	offset := p.offset
+	p.mu.RUnlock()
+	return time.Now().Add(offset)
+}
+
+// Start performs an initial synchronous sync and then re-syncs on a
+// jittered cadence until Stop is called.
+func (p *NTPPool) Start() {
+	p.sync()
+	go func() {
+		for {
+			jitter := time.Duration(rand.Int63n(int64(ntpSyncJitter)))
+			select {
+			case <-time.After(ntpSyncInterval + jitter):
+				p.sync()
+			case <-p.stopCh:
+				return
+			}
 		}
+	}()
+}
+
+// Stop terminates the background re-sync goroutine.
+func (p *NTPPool) Stop() {
+	p.stopOnce.Do(func() { close(p.stopCh) })
+}
+
+type ntpSample struct {
+	offset     time.Duration
+	weight     float64
+	serverAddr string
+}
+
+// sync queries every non-cooldown server, discards outliers using the
+// median absolute deviation, and stores the dispersion-weighted average
+// of what remains.
+func (p *NTPPool) sync() {
+	now := time.Now()
+	samples := make([]ntpSample, 0, len(p.servers))
+
+	for _, s := range p.servers {
+		if s.inCooldown(now) {
+			continue
+		}
+		resp, err := ntp.QueryWithOptions(s.addr, ntp.QueryOptions{Timeout: 5 * time.Second})
+		if err != nil {
+			p.logger.Warn("NTP sync failed", zap.String("ntp_server", s.addr), zap.Error(err))
+			s.recordFailure(err, now)
+			continue
+		}
+		if err := resp.Validate(); err != nil {
+			p.logger.Warn("NTP response failed validation", zap.String("ntp_server", s.addr), zap.Error(err))
+			s.recordFailure(err, now)
+			continue
+		}
+		s.recordSuccess(resp.ClockOffset, now)
+		p.logger.Debug("NTP sync succeeded",
+			zap.String("ntp_server", s.addr),
+			zap.Float64("offset_ms", float64(resp.ClockOffset)/float64(time.Millisecond)),
+		)
+
+		dispersion := resp.RootDispersion.Seconds()
+		if dispersion <= 0 {
+			dispersion = 0.001
+		}
+		weight := 1.0 / (float64(resp.Stratum) * dispersion)
+		samples = append(samples, ntpSample{offset: resp.ClockOffset, weight: weight, serverAddr: s.addr})
+	}
+
+	filtered := rejectOutliers(samples)
+	if len(filtered) == 0 {
+		p.logger.Warn("NTP pool: no healthy servers responded, keeping last known offset")
+		return
+	}
+
+	newOffset := weightedAverageOffset(filtered)
+
+	p.mu.Lock()
+	p.prevOffset = p.offset
+	p.offset = newOffset
+	p.lastSyncAt = now
+	p.mu.Unlock()
+
+	p.logger.Info("NTP pool synced",
+		zap.Int("servers_sampled", len(filtered)),
+		zap.Float64("offset_ms", float64(newOffset)/float64(time.Millisecond)),
+	)
+}
+
+// weightedAverageOffset combines samples into a single clock offset,
+// weighting each server's contribution by the dispersion/stratum-derived
+// weight sync computed for it. Callers must pass a non-empty slice.
+func weightedAverageOffset(samples []ntpSample) time.Duration {
+	var weightedSum, weightSum float64
+	for _, sm := range samples {
+		weightedSum += float64(sm.offset) * sm.weight
+		weightSum += sm.weight
 	}
-	return time.Now().Add(n.offset)
+	return time.Duration(weightedSum / weightSum)
 }
 
-func getNtpOffset(server string) (time.Duration, error) {
-	resp, err := ntp.Query(server)
-	if err != nil {
-		return 0, err
+// rejectOutliers drops samples whose offset deviates from the median by
+// more than 2x the median absolute deviation (MAD). When the MAD is zero,
+// every sample is kept.
+func rejectOutliers(samples []ntpSample) []ntpSample {
+	if len(samples) <= 2 {
+		return samples
+	}
+
+	offsets := make([]float64, len(samples))
+	for i, s := range samples {
+		offsets[i] = float64(s.offset)
+	}
+	median := medianOf(offsets)
+
+	deviations := make([]float64, len(offsets))
+	for i, o := range offsets {
+		deviations[i] = math.Abs(o - median)
+	}
+	mad := medianOf(deviations)
+	if mad == 0 {
+		return samples
+	}
+
+	kept := make([]ntpSample, 0, len(samples))
+	for i, s := range samples {
+		if deviations[i] <= 2*mad {
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) == 0 {
+		return samples
+	}
+	return kept
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// Health reports the currently configured servers, their per-server
+// success/failure counts, and the pool's clock drift since the previous
+// sync.
+func (p *NTPPool) Health() ntpHealthReport {
+	p.mu.RLock()
+	offset := p.offset
+	drift := p.offset - p.prevOffset
+	lastSyncAt := p.lastSyncAt
+	p.mu.RUnlock()
+
+	active := make([]string, 0, len(p.servers))
+	serverHealth := make([]ntpServerHealth, 0, len(p.servers))
+	for _, s := range p.servers {
+		if !s.inCooldown(time.Now()) {
+			active = append(active, s.addr)
+		}
+		serverHealth = append(serverHealth, s.snapshot())
+	}
+
+	return ntpHealthReport{
+		ActiveServers: active,
+		Offset:        float64(offset) / float64(time.Millisecond),
+		DriftMs:       float64(drift) / float64(time.Millisecond),
+		LastSyncAt:    lastSyncAt,
+		Servers:       serverHealth,
+	}
+}
+
+func handleHealthRequest(pool *NTPPool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(pool.Health()); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to encode health report: %v", err), http.StatusInternalServerError)
+		}
+	}
+}
+
+// ServerConfig holds everything Run needs to bring the HTTP(S) server up.
+// It is built from cobra flags in TimeCodeServer and kept separate from the
+// package-level flag vars so Run can be exercised in tests without cobra.
+type ServerConfig struct {
+	Port    int
+	Handler http.Handler
+	Logger  *zap.Logger
+
+	TLSCertFile string
+	TLSKeyFile  string
+
+	ACMEDomain   string
+	ACMEEmail    string
+	ACMECacheDir string
+
+	HSTS bool
+}
+
+// withHSTS adds Strict-Transport-Security to responses served over TLS.
+// It's a no-op over plain HTTP since r.TLS is nil for those requests.
+func withHSTS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			w.Header().Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// shutdownTimeout bounds how long Run waits for in-flight requests to
+// finish once ctx is canceled.
+const shutdownTimeout = 10 * time.Second
+
+// serveUntilDone runs serve in the background and blocks until either it
+// returns or ctx is canceled, in which case srv is given shutdownTimeout to
+// drain in-flight requests before Run returns.
+func serveUntilDone(ctx context.Context, srv *http.Server, logger *zap.Logger, serve func() error) error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- serve() }()
+
+	select {
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("graceful shutdown failed", zap.Error(err))
+			return err
+		}
+		return nil
+	}
+}
+
+// validateTLSConfig rejects TLS configurations that would otherwise make Run
+// silently fall through to plaintext HTTP: a --tls-cert/--tls-key pair where
+// only one side is set (typo or config drift), or both static certs and
+// ACME configured at once (ambiguous which should win).
+func validateTLSConfig(cfg ServerConfig) error {
+	certSet := cfg.TLSCertFile != ""
+	keySet := cfg.TLSKeyFile != ""
+	if certSet != keySet {
+		return fmt.Errorf("--tls-cert and --tls-key must both be set or both be empty (got cert=%q, key=%q)", cfg.TLSCertFile, cfg.TLSKeyFile)
+	}
+	if cfg.ACMEDomain != "" && certSet {
+		return fmt.Errorf("--acme-domain and --tls-cert/--tls-key are mutually exclusive, got both")
+	}
+	return nil
+}
+
+// Run starts the server described by cfg and blocks until it stops, either
+// because ctx is canceled (graceful shutdown) or the listener fails. It
+// chooses between plain HTTP, static-cert TLS, and ACME/autocert TLS based
+// on which fields of cfg are set.
+func Run(ctx context.Context, cfg ServerConfig) error {
+	if err := validateTLSConfig(cfg); err != nil {
+		return err
+	}
+
+	handler := cfg.Handler
+	if cfg.HSTS {
+		handler = withHSTS(handler)
+	}
+
+	switch {
+	case cfg.ACMEDomain != "":
+		return runACME(ctx, cfg, handler)
+	case cfg.TLSCertFile != "" && cfg.TLSKeyFile != "":
+		srv := &http.Server{Addr: fmt.Sprintf(":%d", cfg.Port), Handler: handler}
+		cfg.Logger.Info("listening (TLS)", zap.Int("port", cfg.Port), zap.String("cert", cfg.TLSCertFile))
+		return serveUntilDone(ctx, srv, cfg.Logger, func() error {
+			return srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		})
+	default:
+		srv := &http.Server{Addr: fmt.Sprintf(":%d", cfg.Port), Handler: handler}
+		cfg.Logger.Info("listening", zap.Int("port", cfg.Port))
+		return serveUntilDone(ctx, srv, cfg.Logger, srv.ListenAndServe)
+	}
+}
+
+// runACME serves handler over TLS using a Let's Encrypt certificate fetched
+// and renewed by autocert, and runs a second :80 listener that answers the
+// ACME HTTP-01 challenge and redirects every other request to HTTPS.
+func runACME(ctx context.Context, cfg ServerConfig, handler http.Handler) error {
+	cacheDir := cfg.ACMECacheDir
+	if cacheDir == "" {
+		cacheDir = "acme-cache"
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.ACMEDomain),
+		Cache:      autocert.DirCache(cacheDir),
+		Email:      cfg.ACMEEmail,
+	}
+
+	redirectToHTTPS := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := "https://" + cfg.ACMEDomain
+		if cfg.Port != 443 {
+			target = fmt.Sprintf("%s:%d", target, cfg.Port)
+		}
+		http.Redirect(w, r, target+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+
+	challengeSrv := &http.Server{Addr: ":80", Handler: manager.HTTPHandler(redirectToHTTPS)}
+	tlsSrv := &http.Server{
+		Addr:      fmt.Sprintf(":%d", cfg.Port),
+		Handler:   handler,
+		TLSConfig: manager.TLSConfig(),
+	}
+
+	cfg.Logger.Info("listening (ACME)", zap.String("domain", cfg.ACMEDomain), zap.Int("port", cfg.Port))
+
+	errCh := make(chan error, 2)
+	go func() { errCh <- challengeSrv.ListenAndServe() }()
+	go func() { errCh <- tlsSrv.ListenAndServeTLS("", "") }()
+
+	select {
+	case err := <-errCh:
+		if err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := challengeSrv.Shutdown(shutdownCtx); err != nil {
+			cfg.Logger.Warn("ACME challenge server shutdown failed", zap.Error(err))
+		}
+		if err := tlsSrv.Shutdown(shutdownCtx); err != nil {
+			cfg.Logger.Warn("ACME TLS server shutdown failed", zap.Error(err))
+		}
+		return nil
 	}
-	return resp.ClockOffset, nil
 }
 
 func TimeCodeServer(cmd *cobra.Command, args []string) {
@@ -65,19 +620,48 @@ func TimeCodeServer(cmd *cobra.Command, args []string) {
 
 	fmt.Println(style.Render(fmt.Sprintf("OBS Timecode Server starting on :%d", port)))
 	fmt.Println(lipgloss.NewStyle().Italic(true).Render(fmt.Sprintf("Default FPS set to: %d. Lua client can override via /config endpoint.", defaultFps)))
-	if debug {
-		fmt.Println(lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD700")).Render("[DEBUG] Verbose output enabled."))
+
+	trustedProxies, err := parseTrustedProxies(trustedProxyCIDRs, trustForwardedHeaders)
+	if err != nil {
+		appLogger.Fatal("invalid trusted proxies configuration", zap.Error(err))
+	}
+
+	ntpPool := newNTPPool(ntpServers, appLogger)
+	ntpPool.Start()
+	timeService.SetTimeProvider(ntpPool.Now)
+
+	server.StartDisconnectLogger(appLogger)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/timecode", server.HandleTimecodeRequestWithLog(timeService, appLogger, trustedProxies))
+	mux.HandleFunc("/config", server.HandleConfigRequestWithLog(timeService, appLogger, trustedProxies))
+	mux.HandleFunc("/jam", server.HandleJamRequest(timeService, appLogger, trustedProxies))
+	mux.HandleFunc("/health", handleHealthRequest(ntpPool))
+
+	if metricsEnabled {
+		metricsRegistry.MustRegister(ntpPool)
+		mux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
 	}
 
-	timeProvider := newNtpTimeProvider(ntpServer)
-	timeService.SetTimeProvider(func() time.Time { return timeProvider.Now() })
+	cfg := ServerConfig{
+		Port:         port,
+		Handler:      mux,
+		Logger:       appLogger,
+		TLSCertFile:  tlsCertFile,
+		TLSKeyFile:   tlsKeyFile,
+		ACMEDomain:   acmeDomain,
+		ACMEEmail:    acmeEmail,
+		ACMECacheDir: acmeCacheDir,
+		HSTS:         hstsEnabled,
+	}
 
-	http.HandleFunc("/timecode", server.HandleTimecodeRequestWithLog(timeService, debug))
-	http.HandleFunc("/config", server.HandleConfigRequestWithLog(timeService, debug))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	log.Printf("Listening on port %d...\n", port)
-	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), nil); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	err = Run(ctx, cfg)
+	ntpPool.Stop()
+	if err != nil {
+		appLogger.Fatal("failed to start server", zap.Error(err))
 	}
 }
 
@@ -85,9 +669,23 @@ var rootCmd = &cobra.Command{
 	Use:   "obs-timecodeserver",
 	Short: "A precise timecode server for OBS",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		logger, err := buildLogger(logLevel, logFormat)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		appLogger = logger
+
+		opts := []server.Option{server.WithLogger(appLogger)}
+		if metricsEnabled {
+			metricsRegistry = prometheus.NewRegistry()
+			appMetrics = server.NewMetrics(metricsRegistry)
+			opts = append(opts, server.WithMetrics(appMetrics))
+		}
+
 		// Initialize TimecodeService with default FPS
 		// This FPS can be overridden by the Lua client via /config
-		timeService = server.NewTimecodeService(defaultFps)
+		timeService = server.NewTimecodeService(defaultFps, opts...)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		TimeCodeServer(cmd, args)
@@ -97,8 +695,18 @@ var rootCmd = &cobra.Command{
 func init() {
 	rootCmd.PersistentFlags().IntVarP(&port, "port", "p", 8080, "Port to run the server on")
 	rootCmd.PersistentFlags().IntVarP(&defaultFps, "fps", "f", 30, "Default frames per second for timecode")
-	rootCmd.PersistentFlags().StringVar(&ntpServer, "ntp", "pool.ntp.org", "NTP server for time synchronization")
-	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug output")
+	rootCmd.PersistentFlags().StringSliceVar(&ntpServers, "ntp", []string{"pool.ntp.org"}, "Comma-separated list of NTP servers to pool and failover across (may also be repeated)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level: debug, info, warn, or error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "console", "Log output format: json or console")
+	rootCmd.PersistentFlags().StringSliceVar(&trustedProxyCIDRs, "trusted-proxies", nil, "Comma-separated CIDRs of reverse proxies allowed to set X-Real-IP/X-Forwarded-For")
+	rootCmd.PersistentFlags().BoolVar(&trustForwardedHeaders, "trust-forwarded-headers", false, "Honor X-Real-IP/X-Forwarded-For from peers in --trusted-proxies")
+	rootCmd.PersistentFlags().StringVar(&tlsCertFile, "tls-cert", "", "Path to a PEM TLS certificate; requires --tls-key")
+	rootCmd.PersistentFlags().StringVar(&tlsKeyFile, "tls-key", "", "Path to the PEM TLS private key matching --tls-cert")
+	rootCmd.PersistentFlags().StringVar(&acmeDomain, "acme-domain", "", "Domain to obtain a Let's Encrypt certificate for via ACME (enables autocert, binds :80 for HTTP-01 challenges)")
+	rootCmd.PersistentFlags().StringVar(&acmeEmail, "acme-email", "", "Contact email registered with Let's Encrypt for ACME notices")
+	rootCmd.PersistentFlags().StringVar(&acmeCacheDir, "acme-cache", "acme-cache", "Directory autocert uses to persist issued certificates across restarts")
+	rootCmd.PersistentFlags().BoolVar(&hstsEnabled, "hsts", false, "Send Strict-Transport-Security on TLS responses")
+	rootCmd.PersistentFlags().BoolVar(&metricsEnabled, "metrics", false, "Expose a /metrics endpoint with Prometheus counters and gauges for requests, connected clients, and NTP health")
 }
 
 func main() {
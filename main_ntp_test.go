@@ -0,0 +1,118 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestMedianOf(t *testing.T) {
+	cases := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"single value", []float64{5}, 5},
+		{"odd length", []float64{3, 1, 2}, 2},
+		{"even length", []float64{1, 2, 3, 4}, 2.5},
+		{"unsorted even length", []float64{40, 10, 30, 20}, 25},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := medianOf(c.values); got != c.want {
+				t.Errorf("medianOf(%v) = %v, want %v", c.values, got, c.want)
+			}
+		})
+	}
+}
+
+func TestRejectOutliersDropsASingleBadServer(t *testing.T) {
+	samples := []ntpSample{
+		{offset: 10 * time.Millisecond, weight: 1, serverAddr: "good-a"},
+		{offset: 11 * time.Millisecond, weight: 1, serverAddr: "good-b"},
+		{offset: 12 * time.Millisecond, weight: 1, serverAddr: "good-c"},
+		{offset: 2 * time.Second, weight: 1, serverAddr: "bad"},
+	}
+
+	got := rejectOutliers(samples)
+
+	if len(got) != 3 {
+		t.Fatalf("rejectOutliers() kept %d samples, want 3: %+v", len(got), got)
+	}
+	for _, sm := range got {
+		if sm.serverAddr == "bad" {
+			t.Errorf("rejectOutliers() kept the outlying sample from %q", sm.serverAddr)
+		}
+	}
+}
+
+func TestRejectOutliersShortCircuitsAtTwoOrFewerSamples(t *testing.T) {
+	cases := [][]ntpSample{
+		nil,
+		{{offset: 10 * time.Millisecond, weight: 1, serverAddr: "a"}},
+		{
+			{offset: 10 * time.Millisecond, weight: 1, serverAddr: "a"},
+			{offset: 5 * time.Second, weight: 1, serverAddr: "b"},
+		},
+	}
+	for _, samples := range cases {
+		got := rejectOutliers(samples)
+		if len(got) != len(samples) {
+			t.Errorf("rejectOutliers(%d samples) = %d samples, want all %d kept unfiltered", len(samples), len(got), len(samples))
+		}
+	}
+}
+
+func TestRejectOutliersIdenticalOffsetsDoNotDivideByZero(t *testing.T) {
+	samples := []ntpSample{
+		{offset: 10 * time.Millisecond, weight: 1, serverAddr: "a"},
+		{offset: 10 * time.Millisecond, weight: 1, serverAddr: "b"},
+		{offset: 10 * time.Millisecond, weight: 1, serverAddr: "c"},
+	}
+
+	got := rejectOutliers(samples)
+
+	if len(got) != len(samples) {
+		t.Fatalf("rejectOutliers() with zero MAD = %d samples, want all %d kept", len(got), len(samples))
+	}
+}
+
+func TestWeightedAverageOffset(t *testing.T) {
+	samples := []ntpSample{
+		{offset: 100 * time.Millisecond, weight: 3, serverAddr: "stratum-1"},
+		{offset: 200 * time.Millisecond, weight: 1, serverAddr: "stratum-3"},
+	}
+
+	got := weightedAverageOffset(samples)
+	want := 125 * time.Millisecond // (100*3 + 200*1) / 4
+	if got != want {
+		t.Errorf("weightedAverageOffset() = %v, want %v", got, want)
+	}
+}
+
+func TestWeightedAverageOffsetSingleSampleIsUnchanged(t *testing.T) {
+	samples := []ntpSample{{offset: 42 * time.Millisecond, weight: 7, serverAddr: "only"}}
+	if got := weightedAverageOffset(samples); got != 42*time.Millisecond {
+		t.Errorf("weightedAverageOffset() = %v, want 42ms", got)
+	}
+}
+
+func TestNTPPoolHealthReportsDriftAndOffset(t *testing.T) {
+	p := newNTPPool([]string{"server-a"}, zap.NewNop())
+	now := time.Now()
+
+	p.mu.Lock()
+	p.prevOffset = 10 * time.Millisecond
+	p.offset = 25 * time.Millisecond
+	p.lastSyncAt = now
+	p.mu.Unlock()
+
+	health := p.Health()
+	if health.Offset != 25 {
+		t.Errorf("Health().Offset = %v, want 25ms", health.Offset)
+	}
+	if health.DriftMs != 15 {
+		t.Errorf("Health().DriftMs = %v, want 15ms", health.DriftMs)
+	}
+}
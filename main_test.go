@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestRunServesPlainHTTPUntilContextCanceled(t *testing.T) {
+	port := freePort(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cfg := ServerConfig{
+		Port: port,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+		Logger: zap.NewNop(),
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- Run(ctx, cfg) }()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+	var resp *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		resp, err = http.Get(url)
+		if err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("server never became reachable: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() returned %v after context cancellation, want nil", err)
+		}
+	case <-time.After(shutdownTimeout + time.Second):
+		t.Fatal("Run() did not return after context cancellation")
+	}
+}
+
+func TestRunRejectsPartialTLSConfigInsteadOfFallingBackToPlaintext(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  ServerConfig
+	}{
+		{"cert without key", ServerConfig{TLSCertFile: "cert.pem"}},
+		{"key without cert", ServerConfig{TLSKeyFile: "key.pem"}},
+		{"acme and static certs both set", ServerConfig{ACMEDomain: "example.com", TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.cfg.Logger = zap.NewNop()
+			if err := Run(context.Background(), c.cfg); err == nil {
+				t.Error("Run() = nil error, want a validation error instead of silently serving plaintext")
+			}
+		})
+	}
+}
+
+func TestWithHSTSOnlySetOverTLS(t *testing.T) {
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := withHSTS(inner)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("plain HTTP response got HSTS header %q, want empty", got)
+	}
+
+	req.TLS = &tls.ConnectionState{}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Strict-Transport-Security"); got == "" {
+		t.Error("TLS response missing Strict-Transport-Security header")
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ClientIP returns the real originating client address for r. If the
+// direct peer (r.RemoteAddr) is not in trusted, proxy headers are never
+// consulted and the direct peer address is returned as-is — this is what
+// keeps an untrusted client from spoofing its address via X-Forwarded-For.
+// When the peer is trusted, X-Real-IP is preferred if present and
+// parseable, otherwise X-Forwarded-For is walked from right (closest
+// proxy) to left, skipping any further trusted-proxy hops, returning the
+// first address that isn't itself trusted.
+func ClientIP(r *http.Request, trusted []netip.Prefix) string {
+	peerAddr := hostOnly(r.RemoteAddr)
+
+	peer, err := netip.ParseAddr(peerAddr)
+	if err != nil || !isTrustedProxy(peer, trusted) {
+		return peerAddr
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		if addr, err := netip.ParseAddr(realIP); err == nil {
+			return addr.String()
+		}
+	}
+
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			candidate := strings.TrimSpace(hops[i])
+			addr, err := netip.ParseAddr(candidate)
+			if err != nil {
+				continue
+			}
+			if !isTrustedProxy(addr, trusted) {
+				return addr.String()
+			}
+		}
+	}
+
+	return peerAddr
+}
+
+func isTrustedProxy(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostOnly strips the port from a RemoteAddr-style "host:port" string,
+// returning the input unchanged if it doesn't have one.
+func hostOnly(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func mustPrefixes(t *testing.T, cidrs ...string) []netip.Prefix {
+	t.Helper()
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		p, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			t.Fatalf("ParsePrefix(%q) failed: %v", cidr, err)
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes
+}
+
+func TestClientIPUntrustedPeerIsNeverOverridden(t *testing.T) {
+	trusted := mustPrefixes(t, "10.0.0.0/8")
+	req := httptest.NewRequest(http.MethodGet, "/timecode", nil)
+	req.RemoteAddr = "203.0.113.5:54321"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	req.Header.Set("X-Real-IP", "198.51.100.7")
+
+	got := ClientIP(req, trusted)
+	want := "203.0.113.5"
+	if got != want {
+		t.Errorf("ClientIP() = %q, want %q (an untrusted peer must not be able to spoof via headers)", got, want)
+	}
+}
+
+func TestClientIPTrustedPeerUsesXRealIP(t *testing.T) {
+	trusted := mustPrefixes(t, "10.0.0.0/8")
+	req := httptest.NewRequest(http.MethodGet, "/timecode", nil)
+	req.RemoteAddr = "10.0.0.1:443"
+	req.Header.Set("X-Real-IP", "198.51.100.7")
+
+	got := ClientIP(req, trusted)
+	want := "198.51.100.7"
+	if got != want {
+		t.Errorf("ClientIP() = %q, want %q", got, want)
+	}
+}
+
+func TestClientIPTrustedPeerWalksForwardedForRightToLeft(t *testing.T) {
+	trusted := mustPrefixes(t, "10.0.0.0/8")
+	req := httptest.NewRequest(http.MethodGet, "/timecode", nil)
+	req.RemoteAddr = "10.0.0.1:443"
+	// Real client, then two trusted proxy hops in traversal order.
+	req.Header.Set("X-Forwarded-For", "198.51.100.7, 10.0.0.2, 10.0.0.1")
+
+	got := ClientIP(req, trusted)
+	want := "198.51.100.7"
+	if got != want {
+		t.Errorf("ClientIP() = %q, want %q", got, want)
+	}
+}
+
+func TestClientIPTrustedPeerMalformedForwardedFor(t *testing.T) {
+	trusted := mustPrefixes(t, "10.0.0.0/8")
+	req := httptest.NewRequest(http.MethodGet, "/timecode", nil)
+	req.RemoteAddr = "10.0.0.1:443"
+	req.Header.Set("X-Forwarded-For", "not-an-ip, , 10.0.0.1")
+
+	got := ClientIP(req, trusted)
+	want := "10.0.0.1"
+	if got != want {
+		t.Errorf("ClientIP() = %q, want %q (malformed entries should be skipped, falling back to the peer)", got, want)
+	}
+}
+
+func TestClientIPIPv6(t *testing.T) {
+	trusted := mustPrefixes(t, "fd00::/8")
+	req := httptest.NewRequest(http.MethodGet, "/timecode", nil)
+	req.RemoteAddr = "[fd00::1]:443"
+	req.Header.Set("X-Forwarded-For", "2001:db8::7")
+
+	got := ClientIP(req, trusted)
+	want := "2001:db8::7"
+	if got != want {
+		t.Errorf("ClientIP() = %q, want %q", got, want)
+	}
+}
+
+func TestClientIPNoTrustedProxiesConfigured(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/timecode", nil)
+	req.RemoteAddr = "10.0.0.1:443"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+
+	got := ClientIP(req, nil)
+	want := "10.0.0.1"
+	if got != want {
+		t.Errorf("ClientIP() = %q, want %q (headers must be ignored with no trusted proxies)", got, want)
+	}
+}
+
+func TestClientIPRemoteAddrWithoutPort(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/timecode", nil)
+	req.RemoteAddr = "10.0.0.1"
+
+	got := ClientIP(req, mustPrefixes(t, "10.0.0.0/8"))
+	want := "10.0.0.1"
+	if got != want {
+		t.Errorf("ClientIP() = %q, want %q", got, want)
+	}
+}
@@ -1,13 +1,28 @@
 package server
 
+// TimecodeFormat selects how the frame component of a timecode is counted
+// and displayed. The integer frame rates (24/25/30/60) are non-drop and
+// use FPS directly; DF2997 and DF5994 apply the SMPTE drop-frame algorithm
+// for the 29.97/59.94 broadcast rates, where FPS still carries the nominal
+// integer rate (30 or 60) and the displayed HH:MM:SS;FF skips the first
+// frame numbers of every minute except each tenth minute.
+type TimecodeFormat string
+
+const (
+	FormatNDF    TimecodeFormat = "NDF"    // non-drop-frame, integer FPS
+	FormatDF2997 TimecodeFormat = "DF2997" // 29.97fps drop-frame
+	FormatDF5994 TimecodeFormat = "DF5994" // 59.94fps drop-frame
+)
+
 // ClientConfig represents the configuration sent by the Lua client
 type ClientConfig struct {
-	SourceName string `json:"source_name"` // For server logging/awareness, not directly used for timegen
-	TimeMode   string `json:"time_mode"`   // "24 Hour", "12 Hour", "12 Hour + AM/PM"
-	ShowFrame  bool   `json:"show_frame"`
-	ShowDate   bool   `json:"show_date"`
-	ShowUTC    bool   `json:"show_utc"`
-	PreText    string `json:"pre_text"`
-	PostText   string `json:"post_text"`
-	FPS        int    `json:"fps"` // Frames per second for frame counting
+	SourceName string         `json:"source_name"` // For server logging/awareness, not directly used for timegen
+	TimeMode   string         `json:"time_mode"`   // "24 Hour", "12 Hour", "12 Hour + AM/PM"
+	Format     TimecodeFormat `json:"format"`      // "", NDF, DF2997, or DF5994; empty defaults to NDF
+	ShowFrame  bool           `json:"show_frame"`
+	ShowDate   bool           `json:"show_date"`
+	ShowUTC    bool           `json:"show_utc"`
+	PreText    string         `json:"pre_text"`
+	PostText   string         `json:"post_text"`
+	FPS        int            `json:"fps"` // Frames per second for frame counting
 }
@@ -1,13 +1,64 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/netip"
+	"strconv"
 	"sync"
 	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
+type loggerCtxKey struct{}
+
+// loggerFromRequest returns the request-scoped logger attached by
+// WithRequestLogging, already annotated with a request_id field, or a
+// no-op logger if the request wasn't routed through that middleware.
+func loggerFromRequest(r *http.Request) *zap.Logger {
+	if logger, ok := r.Context().Value(loggerCtxKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return zap.NewNop()
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// WithRequestLogging wraps next with a per-request correlation ID and logs
+// method/path/remote/status/duration at info level once the request
+// completes. The correlated logger is attached to the request context so
+// downstream handlers can use it for their own debug output.
+func WithRequestLogging(logger *zap.Logger, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestLogger := logger.With(zap.String("request_id", uuid.NewString()))
+		r = r.WithContext(context.WithValue(r.Context(), loggerCtxKey{}, requestLogger))
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next(rec, r)
+
+		requestLogger.Info("request handled",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.String("remote", r.RemoteAddr),
+			zap.Int("status", rec.status),
+			zap.Duration("duration", time.Since(start)),
+		)
+	}
+}
+
 // handles requests to update the server's configuration
 func HandleConfigRequest(ts *TimecodeService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -42,6 +93,13 @@ type ClientTracker struct {
 	timeout time.Duration
 }
 
+// Count returns the number of clients currently tracked as connected.
+func (ct *ClientTracker) Count() int {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return len(ct.clients)
+}
+
 func (ct *ClientTracker) Seen(ip string) (firstSeen bool) {
 	ct.mu.Lock()
 	defer ct.mu.Unlock()
@@ -66,31 +124,41 @@ func (ct *ClientTracker) CleanupAndGetDisconnected() []string {
 }
 
 // Periodically check for disconnects
-func StartDisconnectLogger() {
+func StartDisconnectLogger(logger *zap.Logger) {
 	go func() {
 		for {
 			time.Sleep(10 * time.Second)
 			disconnected := clientTracker.CleanupAndGetDisconnected()
 			for _, ip := range disconnected {
-				fmt.Printf("[INFO] Client disconnected: %s\n", ip)
+				logger.Info("client disconnected", zap.String("remote", ip))
 			}
 		}
 	}()
 }
 
-// wraps HandleConfigRequest to log client connections
-func HandleConfigRequestWithLog(ts *TimecodeService, debug bool) http.HandlerFunc {
+// withMetrics increments ts's request counter for endpoint, labeled by the
+// final HTTP status code. It's a no-op if ts was built without WithMetrics.
+func withMetrics(ts *TimecodeService, endpoint string, next http.HandlerFunc) http.HandlerFunc {
+	if ts.metrics == nil {
+		return next
+	}
 	return func(w http.ResponseWriter, r *http.Request) {
-		ip := r.RemoteAddr
-		if debug {
-			fmt.Printf("[DEBUG] /config endpoint hit from %s\n", ip)
-		} else {
-			if clientTracker.Seen(ip) {
-				fmt.Printf("[INFO] Client connected: %s\n", ip)
-			}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+		ts.metrics.RequestsTotal.WithLabelValues(endpoint, strconv.Itoa(rec.status)).Inc()
+	}
+}
+
+// wraps HandleConfigRequest to log client connections, correlated by request ID
+func HandleConfigRequestWithLog(ts *TimecodeService, logger *zap.Logger, trustedProxies []netip.Prefix) http.HandlerFunc {
+	return WithRequestLogging(logger, withMetrics(ts, "config", func(w http.ResponseWriter, r *http.Request) {
+		ip := ClientIP(r, trustedProxies)
+		loggerFromRequest(r).Debug("/config endpoint hit", zap.String("remote", ip))
+		if clientTracker.Seen(ip) {
+			loggerFromRequest(r).Info("client connected", zap.String("remote", ip))
 		}
 		HandleConfigRequest(ts)(w, r)
-	}
+	}))
 }
 
 // handles requests for the current timecode
@@ -106,33 +174,31 @@ func HandleTimecodeRequest(ts *TimecodeService) http.HandlerFunc {
 	}
 }
 
-// wraps HandleTimecodeRequest to log client connections
-func HandleTimecodeRequestWithLog(ts *TimecodeService, debug bool) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		ip := r.RemoteAddr
-		if debug {
-			fmt.Printf("[DEBUG] /timecode endpoint hit from %s\n", ip)
-		} else {
-			if clientTracker.Seen(ip) {
-				fmt.Printf("[INFO] Client connected: %s\n", ip)
-			}
+// wraps HandleTimecodeRequest to log client connections, correlated by request ID
+func HandleTimecodeRequestWithLog(ts *TimecodeService, logger *zap.Logger, trustedProxies []netip.Prefix) http.HandlerFunc {
+	return WithRequestLogging(logger, withMetrics(ts, "timecode", func(w http.ResponseWriter, r *http.Request) {
+		ip := ClientIP(r, trustedProxies)
+		loggerFromRequest(r).Debug("/timecode endpoint hit", zap.String("remote", ip))
+		if clientTracker.Seen(ip) {
+			loggerFromRequest(r).Info("client connected", zap.String("remote", ip))
 		}
 		HandleTimecodeRequest(ts)(w, r)
-	}
+	}))
 }
 
 // handles requests to jam (set) the server's timecode
-func HandleJamRequest(ts *TimecodeService, debug bool) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
+func HandleJamRequest(ts *TimecodeService, logger *zap.Logger, trustedProxies []netip.Prefix) http.HandlerFunc {
+	return WithRequestLogging(logger, withMetrics(ts, "jam", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
 			return
 		}
 		var req struct {
-			Timecode  string `json:"timecode"`
-			FPS       int    `json:"fps"`
-			Datetime  string `json:"datetime"`
-			Timestamp int64  `json:"timestamp"`
+			Timecode  string         `json:"timecode"`
+			FPS       int            `json:"fps"`
+			Format    TimecodeFormat `json:"format"`
+			Datetime  string         `json:"datetime"`
+			Timestamp int64          `json:"timestamp"`
 		}
 		decoder := json.NewDecoder(r.Body)
 		if err := decoder.Decode(&req); err != nil {
@@ -141,15 +207,24 @@ func HandleJamRequest(ts *TimecodeService, debug bool) http.HandlerFunc {
 		}
 		defer r.Body.Close()
 
-		if debug {
-			fmt.Printf("[DEBUG] /jam endpoint hit: %+v\n", req)
+		ip := ClientIP(r, trustedProxies)
+		loggerFromRequest(r).Debug("/jam endpoint hit",
+			zap.String("remote", ip),
+			zap.String("timecode", req.Timecode),
+			zap.Int("fps", req.FPS),
+			zap.String("format", string(req.Format)),
+			zap.String("datetime", req.Datetime),
+			zap.Int64("timestamp", req.Timestamp),
+		)
+		if clientTracker.Seen(ip) {
+			loggerFromRequest(r).Info("client connected", zap.String("remote", ip))
 		}
 
 		var jamTime time.Time
 		var err error
 		if req.Timecode != "" {
-			// Parse SMPTE timecode string (HH:MM:SS:FF)
-			jamTime, err = ParseSMPTETimecode(req.Timecode, req.FPS)
+			// Parse SMPTE timecode string (HH:MM:SS:FF, or HH:MM:SS;FF for drop-frame)
+			jamTime, err = ParseSMPTETimecode(req.Timecode, req.FPS, req.Format)
 			if err != nil {
 				http.Error(w, "Invalid SMPTE timecode: "+err.Error(), http.StatusBadRequest)
 				return
@@ -170,21 +245,40 @@ func HandleJamRequest(ts *TimecodeService, debug bool) http.HandlerFunc {
 		ts.JamToTime(jamTime)
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprintln(w, "Timecode jammed successfully")
-	}
+	}))
 }
 
-// ParseSMPTETimecode parses a SMPTE timecode string (HH:MM:SS:FF) to time.Time (today's date)
-func ParseSMPTETimecode(tc string, fps int) (time.Time, error) {
+// ParseSMPTETimecode parses a SMPTE timecode string to a time.Time reference
+// suitable for TimecodeService.JamToTime. For FormatDF2997/FormatDF5994 the
+// string must use the drop-frame separator (HH:MM:SS;FF) and is inverted via
+// the SMPTE drop-frame algorithm into an elapsed-frame count; any other
+// format is treated as non-drop and parsed as today's wall-clock time plus a
+// fractional-second offset for the frame number.
+func ParseSMPTETimecode(tc string, fps int, format TimecodeFormat) (time.Time, error) {
 	var h, m, s, f int
 	sep := ":"
 	if len(tc) == 11 && tc[8] == ';' {
 		sep = ";"
 	}
-	_, err := fmt.Sscanf(tc, "%02d:%02d:%02d"+sep+"%02d", &h, &m, &s, &f)
-	if err != nil {
+	if _, err := fmt.Sscanf(tc, "%02d:%02d:%02d"+sep+"%02d", &h, &m, &s, &f); err != nil {
 		return time.Time{}, err
 	}
+
 	now := time.Now()
+
+	if format == FormatDF2997 || format == FormatDF5994 {
+		if sep != ";" {
+			return time.Time{}, fmt.Errorf("drop-frame timecode %q must use ';' before the frame number", tc)
+		}
+		dfFPS, dropFrames := nominalDropFrameRate(format)
+		totalFrames, err := dropFrameLabelToFrames(h, m, s, f, dfFPS, dropFrames)
+		if err != nil {
+			return time.Time{}, err
+		}
+		elapsed := time.Duration(float64(totalFrames) / float64(dfFPS) * float64(time.Second))
+		return now.Add(-elapsed), nil
+	}
+
 	jam := time.Date(now.Year(), now.Month(), now.Day(), h, m, s, int(float64(f)/float64(fps)*1e9), now.Location())
 	return jam, nil
 }
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the Prometheus collectors exposed by the timecode service.
+// Construct with NewMetrics against a dedicated prometheus.Registry (not
+// prometheus.DefaultRegisterer) so embedding this package never pollutes a
+// host process's global metrics.
+type Metrics struct {
+	RequestsTotal    *prometheus.CounterVec
+	ConnectedClients prometheus.Collector
+	TimecodeLatency  prometheus.Histogram
+}
+
+// NewMetrics creates the service's collectors and registers them on reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		RequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "obs_timecode_requests_total",
+			Help: "Total HTTP requests handled, labeled by endpoint and status code.",
+		}, []string{"endpoint", "status"}),
+		ConnectedClients: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "obs_timecode_connected_clients",
+			Help: "Number of Lua clients currently considered connected.",
+		}, func() float64 { return float64(clientTracker.Count()) }),
+		TimecodeLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "obs_timecode_format_duration_seconds",
+			Help:    "Latency of GetFormattedTimecode calls.",
+			Buckets: prometheus.DefBuckets,
+		}),
+	}
+
+	reg.MustRegister(m.RequestsTotal, m.ConnectedClients, m.TimecodeLatency)
+	return m
+}
+
+// observeLatency is a no-op when m is nil, so callers don't need to guard
+// every call site on whether metrics are enabled.
+func (m *Metrics) observeLatency(since time.Time) {
+	if m == nil {
+		return
+	}
+	m.TimecodeLatency.Observe(time.Since(since).Seconds())
+}
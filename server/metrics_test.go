@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+)
+
+func counterValue(t *testing.T, vec *prometheus.CounterVec, labels ...string) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := vec.WithLabelValues(labels...).Write(&m); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestHandleTimecodeRequestWithLogRecordsMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := NewMetrics(reg)
+	ts := NewTimecodeService(30, WithMetrics(metrics))
+
+	handler := HandleTimecodeRequestWithLog(ts, zap.NewNop(), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/timecode", nil)
+	req.RemoteAddr = "203.0.113.5:54321"
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if got := counterValue(t, metrics.RequestsTotal, "timecode", "200"); got != 1 {
+		t.Errorf("requests_total{endpoint=timecode,status=200} = %v, want 1", got)
+	}
+}
+
+func TestGetFormattedTimecodeObservesLatency(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := NewMetrics(reg)
+	ts := NewTimecodeService(30, WithMetrics(metrics))
+
+	ts.GetFormattedTimecode()
+
+	var m dto.Metric
+	if err := metrics.TimecodeLatency.Write(&m); err != nil {
+		t.Fatalf("Write() failed: %v", err)
+	}
+	if got := m.GetHistogram().GetSampleCount(); got != 1 {
+		t.Errorf("format_duration_seconds sample count = %d, want 1", got)
+	}
+}
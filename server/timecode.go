@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"go.uber.org/zap"
 )
 
 type TimeProvider func() time.Time
@@ -15,12 +17,39 @@ type TimecodeService struct {
 	currentFrame      int
 	lastNanosecondDiv int64 // Used for more precise frame reset with monotonic time
 	timeProvider      TimeProvider
+	epoch             time.Time // Drop-frame frame-count reference; replaced by JamToTime
+	logger            *zap.Logger
+	metrics           *Metrics
+}
+
+// Option configures a TimecodeService at construction time.
+type Option func(*TimecodeService)
+
+// WithLogger attaches a *zap.Logger to the service for structured config-
+// update logging. Library users who don't care about logs can omit this;
+// NewTimecodeService defaults to a no-op logger.
+func WithLogger(logger *zap.Logger) Option {
+	return func(ts *TimecodeService) {
+		ts.logger = logger
+	}
+}
+
+// WithMetrics attaches Prometheus collectors to the service for request
+// latency instrumentation. Library users who don't want metrics can omit
+// this; NewTimecodeService leaves metrics nil and all instrumentation
+// becomes a no-op.
+func WithMetrics(m *Metrics) Option {
+	return func(ts *TimecodeService) {
+		ts.metrics = m
+	}
 }
 
-func NewTimecodeService(defaultFPS int) *TimecodeService {
-	return &TimecodeService{
+func NewTimecodeService(defaultFPS int, opts ...Option) *TimecodeService {
+	now := time.Now()
+	ts := &TimecodeService{
 		config: ClientConfig{
 			TimeMode:  "24 Hour",
+			Format:    FormatNDF,
 			ShowFrame: false,
 			ShowDate:  false,
 			ShowUTC:   false,
@@ -29,7 +58,13 @@ func NewTimecodeService(defaultFPS int) *TimecodeService {
 		lastSecond:   -1,
 		currentFrame: 0,
 		timeProvider: time.Now,
+		epoch:        time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+		logger:       zap.NewNop(),
+	}
+	for _, opt := range opts {
+		opt(ts)
 	}
+	return ts
 }
 
 func (ts *TimecodeService) SetTimeProvider(tp TimeProvider) {
@@ -38,6 +73,16 @@ func (ts *TimecodeService) SetTimeProvider(tp TimeProvider) {
 	ts.timeProvider = tp
 }
 
+// JamToTime re-jams the drop-frame frame-count epoch so that, from this
+// moment on, GetFormattedTimecode's elapsed-frame calculation is anchored
+// to reference. Callers typically obtain reference from ParseSMPTETimecode
+// (for a jam-to-timecode request) or a parsed datetime/timestamp.
+func (ts *TimecodeService) JamToTime(reference time.Time) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.epoch = reference
+}
+
 func (ts *TimecodeService) UpdateConfig(newConfig ClientConfig) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
@@ -49,10 +94,17 @@ func (ts *TimecodeService) UpdateConfig(newConfig ClientConfig) {
 	ts.currentFrame = 0
 	ts.lastNanosecondDiv = -1
 
-	fmt.Printf("Server config updated: %+v\n", ts.config)
+	ts.logger.Info("config updated",
+		zap.Int("fps", ts.config.FPS),
+		zap.String("time_mode", ts.config.TimeMode),
+		zap.String("format", string(ts.config.Format)),
+		zap.Bool("show_frame", ts.config.ShowFrame),
+	)
 }
 
 func (ts *TimecodeService) GetFormattedTimecode() string {
+	defer ts.metrics.observeLatency(time.Now())
+
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
@@ -61,6 +113,10 @@ func (ts *TimecodeService) GetFormattedTimecode() string {
 		now = now.UTC()
 	}
 
+	if ts.config.Format == FormatDF2997 || ts.config.Format == FormatDF5994 {
+		return ts.formatDropFrameLocked(now)
+	}
+
 	// --- Monotonic, elapsed-time-based frame calculation ---
 	var frameStr string
 	if ts.config.ShowFrame {
@@ -101,3 +157,97 @@ func (ts *TimecodeService) GetFormattedTimecode() string {
 
 	return fmt.Sprintf("%s%s%s%s%s%s", ts.config.PreText, dateStr, timeStr, frameStr, ampmStr, ts.config.PostText)
 }
+
+// nominalDropFrameRate returns the nominal integer FPS and the number of
+// frame numbers skipped per minute (except every tenth) for a drop-frame
+// format. Callers must only pass FormatDF2997 or FormatDF5994.
+func nominalDropFrameRate(format TimecodeFormat) (fps, dropFrames int) {
+	if format == FormatDF5994 {
+		return 60, 4
+	}
+	return 30, 2
+}
+
+// formatDropFrameLocked renders the current SMPTE drop-frame timecode.
+// ts.mu must already be held. The elapsed time since ts.epoch is converted
+// to a continuous frame count at the nominal FPS (30 or 60), then
+// dropFrameLabel turns that count into an HH:MM:SS;FF label. Because the
+// count runs at the nominal rate rather than 29.97/59.94, the label gains
+// about 3.6 seconds per hour of elapsed wall-clock time.
+func (ts *TimecodeService) formatDropFrameLocked(now time.Time) string {
+	fps, dropFrames := nominalDropFrameRate(ts.config.Format)
+
+	elapsed := now.Sub(ts.epoch)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	totalFrames := int64(elapsed.Seconds() * float64(fps))
+
+	h, m, s, f := dropFrameLabel(totalFrames, fps, dropFrames)
+	timeStr := fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+
+	frameStr := ""
+	if ts.config.ShowFrame {
+		frameStr = fmt.Sprintf(";%02d", f)
+	}
+
+	dateStr := ""
+	if ts.config.ShowDate {
+		dateStr = now.Format("2006-01-02 ")
+	}
+
+	return fmt.Sprintf("%s%s%s%s%s", ts.config.PreText, dateStr, timeStr, frameStr, ts.config.PostText)
+}
+
+// dropFrameLabel converts a continuous frame count (no gaps) into the
+// HH:MM:SS;FF label a drop-frame SMPTE clock would display. Each 10-minute
+// cycle is one exempt minute (the full fps*60 frames, no drop) followed by
+// nine non-exempt minutes that each skip the first dropFrames frame
+// numbers, so the label tracks wall-clock time despite the 29.97/59.94
+// actual frame rate.
+func dropFrameLabel(frameNumber int64, fps, dropFrames int) (h, m, s, f int) {
+	framesPerNonExemptMinute := int64(fps)*60 - int64(dropFrames)
+	framesPerExemptMinute := int64(fps) * 60
+	framesPer10Minutes := framesPerExemptMinute + 9*framesPerNonExemptMinute
+	framesPer24Hours := int64(fps) * 3600 * 24
+
+	frameNumber = ((frameNumber % framesPer24Hours) + framesPer24Hours) % framesPer24Hours
+
+	block := frameNumber / framesPer10Minutes
+	posInBlock := frameNumber % framesPer10Minutes
+
+	var minuteInBlock, labelFrameNumber int64
+	if posInBlock < framesPerExemptMinute {
+		minuteInBlock = 0
+		labelFrameNumber = posInBlock
+	} else {
+		rest := posInBlock - framesPerExemptMinute
+		minuteInBlock = 1 + rest/framesPerNonExemptMinute
+		labelFrameNumber = rest%framesPerNonExemptMinute + int64(dropFrames)
+	}
+
+	absMinute := block*10 + minuteInBlock
+	fr := int64(fps)
+	f = int(labelFrameNumber % fr)
+	s = int(labelFrameNumber / fr)
+	m = int(absMinute % 60)
+	h = int((absMinute / 60) % 24)
+	return h, m, s, f
+}
+
+// dropFrameLabelToFrames inverts dropFrameLabel: given an HH:MM:SS;FF
+// drop-frame label, it returns the continuous frame count (no gaps) that
+// produces it. It rejects frame numbers that drop-frame timecode skips
+// (e.g. 00:01:00;00 and 00:01:00;01 don't exist for 29.97).
+func dropFrameLabelToFrames(h, m, s, f, fps, dropFrames int) (int64, error) {
+	if h < 0 || h > 23 || m < 0 || m > 59 || s < 0 || s > 59 || f < 0 || f >= fps {
+		return 0, fmt.Errorf("timecode component out of range: %02d:%02d:%02d;%02d", h, m, s, f)
+	}
+	if s == 0 && f < dropFrames && m%10 != 0 {
+		return 0, fmt.Errorf("frame %02d does not exist in drop-frame timecode at %02d:%02d;%02d", f, h, m, f)
+	}
+
+	totalMinutes := int64(60*h + m)
+	literal := int64(fps)*3600*int64(h) + int64(fps)*60*int64(m) + int64(fps)*int64(s) + int64(f)
+	return literal - int64(dropFrames)*(totalMinutes-totalMinutes/10), nil
+}
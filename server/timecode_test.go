@@ -0,0 +1,122 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+// Reference values are the well-known SMPTE drop-frame checkpoints: one
+// hour of 29.97fps drop-frame is 107892 frames (30*3600 - 2*54 dropped
+// labels), and one hour of 59.94fps drop-frame is 215784 frames.
+func TestDropFrameLabel(t *testing.T) {
+	cases := []struct {
+		name        string
+		frameNumber int64
+		fps         int
+		dropFrames  int
+		wantH       int
+		wantM       int
+		wantS       int
+		wantF       int
+	}{
+		{"zero", 0, 30, 2, 0, 0, 0, 0},
+		{"29.97 just before first drop", 1799, 30, 2, 0, 0, 59, 29},
+		{"29.97 first drop lands on ;02", 1800, 30, 2, 0, 1, 0, 2},
+		{"29.97 tenth minute has no drop", 17982, 30, 2, 0, 10, 0, 0},
+		{"29.97 one hour reference", 107892, 30, 2, 1, 0, 0, 0},
+		{"59.94 one hour reference", 215784, 60, 4, 1, 0, 0, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h, m, s, f := dropFrameLabel(c.frameNumber, c.fps, c.dropFrames)
+			if h != c.wantH || m != c.wantM || s != c.wantS || f != c.wantF {
+				t.Errorf("dropFrameLabel(%d, %d, %d) = %02d:%02d:%02d;%02d, want %02d:%02d:%02d;%02d",
+					c.frameNumber, c.fps, c.dropFrames, h, m, s, f, c.wantH, c.wantM, c.wantS, c.wantF)
+			}
+		})
+	}
+}
+
+func TestDropFrameLabelToFrames(t *testing.T) {
+	cases := []struct {
+		name                        string
+		h, m, s, f, fps, dropFrames int
+		want                        int64
+		wantErr                     bool
+	}{
+		{"zero", 0, 0, 0, 0, 30, 2, 0, false},
+		{"29.97 just before first drop", 0, 0, 59, 29, 30, 2, 1799, false},
+		{"29.97 first drop lands on ;02", 0, 1, 0, 2, 30, 2, 1800, false},
+		{"29.97 one hour reference", 1, 0, 0, 0, 30, 2, 107892, false},
+		{"59.94 one hour reference", 1, 0, 0, 0, 60, 4, 215784, false},
+		{"29.97 rejects dropped frame number 00", 0, 1, 0, 0, 30, 2, 0, true},
+		{"29.97 rejects dropped frame number 01", 0, 1, 0, 1, 30, 2, 0, true},
+		{"29.97 allows ;00 on exempt tenth minute", 0, 10, 0, 0, 30, 2, 17982, false},
+		{"rejects out-of-range frame", 0, 0, 0, 30, 30, 2, 0, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := dropFrameLabelToFrames(c.h, c.m, c.s, c.f, c.fps, c.dropFrames)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("dropFrameLabelToFrames(%02d:%02d:%02d;%02d) = %d, want error", c.h, c.m, c.s, c.f, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("dropFrameLabelToFrames(%02d:%02d:%02d;%02d) returned unexpected error: %v", c.h, c.m, c.s, c.f, err)
+			}
+			if got != c.want {
+				t.Errorf("dropFrameLabelToFrames(%02d:%02d:%02d;%02d) = %d, want %d", c.h, c.m, c.s, c.f, got, c.want)
+			}
+		})
+	}
+}
+
+func TestDropFrameRoundTrip(t *testing.T) {
+	for _, frameNumber := range []int64{0, 1799, 1800, 17982, 17983, 107892, 1_000_000} {
+		h, m, s, f := dropFrameLabel(frameNumber, 30, 2)
+		got, err := dropFrameLabelToFrames(h, m, s, f, 30, 2)
+		if err != nil {
+			t.Fatalf("round trip for frame %d failed: %v", frameNumber, err)
+		}
+		want := frameNumber % (30 * 3600 * 24)
+		if got != want {
+			t.Errorf("round trip for frame %d = %d, want %d", frameNumber, got, want)
+		}
+	}
+}
+
+func TestParseSMPTETimecodeDropFrame(t *testing.T) {
+	ref, err := ParseSMPTETimecode("01:00:00;00", 30, FormatDF2997)
+	if err != nil {
+		t.Fatalf("ParseSMPTETimecode returned unexpected error: %v", err)
+	}
+
+	elapsed := time.Since(ref)
+	wantElapsed := time.Duration(float64(107892) / 30 * float64(time.Second))
+	if diff := elapsed - wantElapsed; diff > time.Second || diff < -time.Second {
+		t.Errorf("elapsed since reference = %v, want ~%v", elapsed, wantElapsed)
+	}
+}
+
+func TestParseSMPTETimecodeDropFrameRequiresSemicolon(t *testing.T) {
+	if _, err := ParseSMPTETimecode("01:00:00:00", 30, FormatDF2997); err == nil {
+		t.Error("expected error for drop-frame timecode using ':' before the frame number")
+	}
+}
+
+func TestGetFormattedTimecodeDropFrame(t *testing.T) {
+	ts := NewTimecodeService(30)
+	ts.UpdateConfig(ClientConfig{Format: FormatDF2997, ShowFrame: true, FPS: 30})
+
+	fixedNow := time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC)
+	ts.JamToTime(fixedNow.Add(-time.Duration(float64(107892) / 30 * float64(time.Second))))
+	ts.SetTimeProvider(func() time.Time { return fixedNow })
+
+	got := ts.GetFormattedTimecode()
+	want := "01:00:00;00"
+	if got != want {
+		t.Errorf("GetFormattedTimecode() = %q, want %q", got, want)
+	}
+}